@@ -0,0 +1,56 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/BurntSushi/toml"
+)
+
+// CityConfig is the on-disk representation of a monitored city in the TOML
+// registry. OWMCityID is optional (zero means "not set"): cities with an ID
+// are eligible for OpenWeatherMap's batched /group endpoint, cities without
+// one always fall back to a per-coordinate fetch.
+type CityConfig struct {
+	Name          string  `toml:"name"`
+	Lat           float64 `toml:"lat"`
+	Lon           float64 `toml:"lon"`
+	OWMCityID     int     `toml:"owm_city_id"`
+	PollingWeight int     `toml:"polling_weight"`
+}
+
+// cityRegistryFile mirrors the top-level shape of cities.toml: a list of
+// [[city]] tables.
+type cityRegistryFile struct {
+	Cities []CityConfig `toml:"city"`
+}
+
+// LoadCityRegistry reads the city registry TOML file at path and returns the
+// configured cities as CityInfo. A city with no polling_weight set defaults
+// to weight 1.
+func LoadCityRegistry(path string) ([]CityInfo, error) {
+	var registry cityRegistryFile
+	if _, err := toml.DecodeFile(path, &registry); err != nil {
+		return nil, fmt.Errorf("failed to load city registry from %s: %w", path, err)
+	}
+
+	if len(registry.Cities) == 0 {
+		return nil, fmt.Errorf("city registry %s defines no cities", path)
+	}
+
+	cities := make([]CityInfo, 0, len(registry.Cities))
+	for _, c := range registry.Cities {
+		weight := c.PollingWeight
+		if weight == 0 {
+			weight = 1
+		}
+		cities = append(cities, CityInfo{
+			Name:          c.Name,
+			Lat:           c.Lat,
+			Lon:           c.Lon,
+			OWMCityID:     c.OWMCityID,
+			PollingWeight: weight,
+		})
+	}
+
+	return cities, nil
+}
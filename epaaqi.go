@@ -0,0 +1,122 @@
+package main
+
+import (
+	"log"
+	"math"
+)
+
+// epaBreakpoint is one row of an EPA AQI breakpoint table: a pollutant
+// concentration range (CLo-CHi) mapped to an AQI range (ILo-IHi).
+type epaBreakpoint struct {
+	CLo, CHi float64
+	ILo, IHi int
+}
+
+// EPA breakpoint tables, in the units ComputeEPAAQI converts each pollutant
+// into before lookup: PM2.5/PM10 in µg/m3, O3/SO2/NO2 in ppb, CO in ppm.
+var (
+	pm25Breakpoints = []epaBreakpoint{
+		{0.0, 12.0, 0, 50},
+		{12.1, 35.4, 51, 100},
+		{35.5, 55.4, 101, 150},
+		{55.5, 150.4, 151, 200},
+		{150.5, 250.4, 201, 300},
+		{250.5, 500.4, 301, 500},
+	}
+	pm10Breakpoints = []epaBreakpoint{
+		{0, 54, 0, 50},
+		{55, 154, 51, 100},
+		{155, 254, 101, 150},
+		{255, 354, 151, 200},
+		{355, 424, 201, 300},
+		{425, 604, 301, 500},
+	}
+	o3Breakpoints = []epaBreakpoint{
+		{0, 54, 0, 50},
+		{55, 70, 51, 100},
+		{71, 85, 101, 150},
+		{86, 105, 151, 200},
+		{106, 200, 201, 300},
+	}
+	coBreakpoints = []epaBreakpoint{
+		{0, 4, 0, 50},
+		{5, 9, 51, 100},
+		{10, 12, 101, 150},
+		{13, 15, 151, 200},
+		{16, 30, 201, 300},
+		{31, 50, 301, 500},
+	}
+	so2Breakpoints = []epaBreakpoint{
+		{0, 35, 0, 50},
+		{36, 75, 51, 100},
+		{76, 185, 101, 150},
+		{186, 304, 151, 200},
+		{305, 604, 201, 300},
+		{605, 1004, 301, 500},
+	}
+	no2Breakpoints = []epaBreakpoint{
+		{0, 53, 0, 50},
+		{54, 100, 51, 100},
+		{101, 360, 101, 150},
+		{361, 649, 151, 200},
+		{650, 1249, 201, 300},
+		{1250, 2049, 301, 500},
+	}
+)
+
+// truncateTo truncates (not rounds) value to the given number of decimal
+// places, matching the EPA's "always round down" convention.
+func truncateTo(value float64, decimals int) float64 {
+	factor := math.Pow(10, float64(decimals))
+	return math.Trunc(value*factor) / factor
+}
+
+// lookupEPAAQI finds the breakpoint row containing conc and applies the
+// standard piecewise-linear formula. ok is false if conc exceeds every
+// breakpoint in the table.
+func lookupEPAAQI(conc float64, table []epaBreakpoint) (aqi int, ok bool) {
+	for _, bp := range table {
+		if conc >= bp.CLo && conc <= bp.CHi {
+			i := (float64(bp.IHi-bp.ILo))/(bp.CHi-bp.CLo)*(conc-bp.CLo) + float64(bp.ILo)
+			return int(math.Round(i)), true
+		}
+	}
+	return 0, false
+}
+
+// ComputeEPAAQI derives the US EPA Air Quality Index from raw pollutant
+// concentrations, since OpenWeatherMap's own `main.aqi` is only a coarse
+// 1-5 bucket. It returns the overall AQI (the max across pollutants) and
+// the name of the pollutant that produced it. Concentrations above the top
+// breakpoint of every table fall back to 500 (Hazardous) with a logged
+// warning rather than silently under-reporting.
+func ComputeEPAAQI(components AQIData) (aqi int, dominant string) {
+	type candidate struct {
+		name  string
+		conc  float64
+		table []epaBreakpoint
+	}
+
+	candidates := []candidate{
+		{"PM2.5", truncateTo(components.PM2_5, 1), pm25Breakpoints},
+		{"PM10", math.Trunc(components.PM10), pm10Breakpoints},
+		{"O3", math.Trunc(components.O3 / 1.96), o3Breakpoints},    // µg/m3 -> ppb
+		{"CO", math.Trunc(components.CO / 1145), coBreakpoints},    // µg/m3 -> ppm
+		{"SO2", math.Trunc(components.SO2 / 2.62), so2Breakpoints}, // µg/m3 -> ppb
+		{"NO2", math.Trunc(components.NO2 / 1.88), no2Breakpoints}, // µg/m3 -> ppb
+	}
+
+	for _, c := range candidates {
+		pollutantAQI, ok := lookupEPAAQI(c.conc, c.table)
+		if !ok {
+			log.Printf("WARNING: %s concentration %.2f exceeds the top EPA breakpoint; falling back to AQI 500", c.name, c.conc)
+			pollutantAQI = 500
+		}
+		if pollutantAQI > aqi {
+			aqi = pollutantAQI
+			dominant = c.name
+		}
+	}
+
+	return aqi, dominant
+}
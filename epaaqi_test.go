@@ -0,0 +1,105 @@
+package main
+
+import "testing"
+
+func TestLookupEPAAQI(t *testing.T) {
+	tests := []struct {
+		name    string
+		conc    float64
+		table   []epaBreakpoint
+		wantAQI int
+		wantOK  bool
+	}{
+		// PM2.5: concentrations are truncated to 1 decimal before lookup, so
+		// adjacent rows meet one tenth apart (12.0/12.1, 35.4/35.5).
+		{"pm2.5 bottom of table", 0.0, pm25Breakpoints, 0, true},
+		{"pm2.5 top of first row", 12.0, pm25Breakpoints, 50, true},
+		{"pm2.5 bottom of second row", 12.1, pm25Breakpoints, 51, true},
+		{"pm2.5 top of second row", 35.4, pm25Breakpoints, 100, true},
+		{"pm2.5 bottom of third row", 35.5, pm25Breakpoints, 101, true},
+		{"pm2.5 top of table", 500.4, pm25Breakpoints, 500, true},
+		{"pm2.5 above table", 500.5, pm25Breakpoints, 0, false},
+
+		// PM10/O3/CO/SO2/NO2: concentrations are truncated to whole numbers
+		// before lookup, so boundaries fall on integers.
+		{"pm10 top of first row", 54, pm10Breakpoints, 50, true},
+		{"pm10 bottom of second row", 55, pm10Breakpoints, 51, true},
+		{"pm10 top of table", 604, pm10Breakpoints, 500, true},
+		{"pm10 above table", 605, pm10Breakpoints, 0, false},
+
+		{"o3 top of first row", 54, o3Breakpoints, 50, true},
+		{"o3 bottom of second row", 55, o3Breakpoints, 51, true},
+		{"o3 top of table", 200, o3Breakpoints, 300, true},
+		{"o3 above table", 201, o3Breakpoints, 0, false},
+
+		{"co top of first row", 4, coBreakpoints, 50, true},
+		{"co bottom of second row", 5, coBreakpoints, 51, true},
+		{"co top of table", 50, coBreakpoints, 500, true},
+		{"co above table", 51, coBreakpoints, 0, false},
+
+		{"so2 top of first row", 35, so2Breakpoints, 50, true},
+		{"so2 bottom of second row", 36, so2Breakpoints, 51, true},
+		{"so2 top of table", 1004, so2Breakpoints, 500, true},
+		{"so2 above table", 1005, so2Breakpoints, 0, false},
+
+		{"no2 top of first row", 53, no2Breakpoints, 50, true},
+		{"no2 bottom of second row", 54, no2Breakpoints, 51, true},
+		{"no2 top of table", 2049, no2Breakpoints, 500, true},
+		{"no2 above table", 2050, no2Breakpoints, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAQI, gotOK := lookupEPAAQI(tt.conc, tt.table)
+			if gotOK != tt.wantOK {
+				t.Fatalf("lookupEPAAQI(%v) ok = %v, want %v", tt.conc, gotOK, tt.wantOK)
+			}
+			if gotOK && gotAQI != tt.wantAQI {
+				t.Fatalf("lookupEPAAQI(%v) = %d, want %d", tt.conc, gotAQI, tt.wantAQI)
+			}
+		})
+	}
+}
+
+func TestComputeEPAAQI(t *testing.T) {
+	tests := []struct {
+		name         string
+		components   AQIData
+		wantAQI      int
+		wantDominant string
+	}{
+		{
+			name:         "clean air, all pollutants at zero",
+			components:   AQIData{},
+			wantAQI:      0,
+			wantDominant: "",
+		},
+		{
+			name:         "PM2.5 dominant at a moderate reading",
+			components:   AQIData{PM2_5: 20.0, PM10: 10, O3: 20, CO: 500, SO2: 10, NO2: 20},
+			wantAQI:      68,
+			wantDominant: "PM2.5",
+		},
+		{
+			name:         "NO2 dominant, unit conversion from ug/m3 to ppb",
+			components:   AQIData{PM2_5: 1, PM10: 1, O3: 1, CO: 1, SO2: 1, NO2: 2000},
+			wantAQI:      269,
+			wantDominant: "NO2",
+		},
+		{
+			name:         "PM2.5 above the top breakpoint falls back to 500",
+			components:   AQIData{PM2_5: 600.0},
+			wantAQI:      500,
+			wantDominant: "PM2.5",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotAQI, gotDominant := ComputeEPAAQI(tt.components)
+			if gotAQI != tt.wantAQI || gotDominant != tt.wantDominant {
+				t.Fatalf("ComputeEPAAQI(%+v) = (%d, %q), want (%d, %q)", tt.components, gotAQI, gotDominant, tt.wantAQI, tt.wantDominant)
+			}
+		})
+	}
+}
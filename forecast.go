@@ -0,0 +1,289 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// ForecastData represents a single 3-hour forecast slot from OpenWeatherMap's
+// /forecast endpoint.
+type ForecastData struct {
+	ForecastTime time.Time `json:"forecast_time"`
+	Temperature  float64   `json:"temperature"`
+	Humidity     int       `json:"humidity"`
+	WindSpeed    float64   `json:"wind_speed"`
+	Conditions   string    `json:"conditions"`
+	RainVolume3h *float64  `json:"rain_volume_3h,omitempty"`
+	SnowVolume3h *float64  `json:"snow_volume_3h,omitempty"`
+}
+
+// ForecastAQIData represents a single predicted air-pollution slot from
+// OpenWeatherMap's /air_pollution/forecast endpoint.
+type ForecastAQIData struct {
+	ForecastTime time.Time `json:"forecast_time"`
+	AQI          int       `json:"aqi"`
+	CO           float64   `json:"co"`
+	NO           float64   `json:"no"`
+	NO2          float64   `json:"no2"`
+	O3           float64   `json:"o3"`
+	SO2          float64   `json:"so2"`
+	PM2_5        float64   `json:"pm2_5"`
+	PM10         float64   `json:"pm10"`
+	NH3          float64   `json:"nh3"`
+}
+
+// ForecastPoint combines a predicted weather slot and predicted AQI slot for
+// a single city/forecast_time, tagged with the time the prediction was
+// issued so repeated fetches can be told apart from stale runs.
+type ForecastPoint struct {
+	City         string    `bson:"city"`
+	Latitude     float64   `bson:"latitude"`
+	Longitude    float64   `bson:"longitude"`
+	ForecastTime time.Time `bson:"forecast_time"`
+	IssuedAt     time.Time `bson:"issued_at"`
+	Temperature  float64   `bson:"temperature"`
+	Humidity     int       `bson:"humidity"`
+	WindSpeed    float64   `bson:"wind_speed"`
+	Conditions   string    `bson:"conditions"`
+	RainVolume3h *float64  `bson:"rain_volume_3h,omitempty"`
+	SnowVolume3h *float64  `bson:"snow_volume_3h,omitempty"`
+	// AQIAvailable is false when no AQI forecast slot fell within
+	// aqiForecastMatchTolerance of ForecastTime; the AQI/pollutant fields
+	// below are then left at their zero value and should not be read as
+	// "clean air".
+	AQIAvailable bool    `bson:"aqi_available"`
+	AQI          int     `bson:"aqi"`
+	CO           float64 `bson:"co"`
+	NO           float64 `bson:"no"`
+	NO2          float64 `bson:"no2"`
+	O3           float64 `bson:"o3"`
+	SO2          float64 `bson:"so2"`
+	PM2_5        float64 `bson:"pm2_5"`
+	PM10         float64 `bson:"pm10"`
+	NH3          float64 `bson:"nh3"`
+}
+
+// FetchForecast makes an API call to OpenWeatherMap to get the 5-day/3-hour
+// weather forecast for the given latitude and longitude.
+func (c *OpenWeatherMapClient) FetchForecast(ctx context.Context, lat, lon float64) ([]ForecastData, error) {
+	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/forecast?lat=%f&lon=%f&appid=%s&units=metric", lat, lon, c.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create forecast HTTP request: %w", err)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute forecast HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenWeatherMap forecast API returned non-OK status: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var owmResponse struct {
+		List []struct {
+			Dt   int64 `json:"dt"`
+			Main struct {
+				Temp     float64 `json:"temp"`
+				Humidity int     `json:"humidity"`
+			} `json:"main"`
+			Wind struct {
+				Speed float64 `json:"speed"`
+			} `json:"wind"`
+			Weather []struct {
+				Description string `json:"description"`
+			} `json:"weather"`
+			Rain struct {
+				ThreeH float64 `json:"3h"`
+			} `json:"rain"`
+			Snow struct {
+				ThreeH float64 `json:"3h"`
+			} `json:"snow"`
+		} `json:"list"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&owmResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenWeatherMap forecast API response: %w", err)
+	}
+
+	forecast := make([]ForecastData, 0, len(owmResponse.List))
+	for _, slot := range owmResponse.List {
+		conditions := ""
+		if len(slot.Weather) > 0 {
+			conditions = slot.Weather[0].Description
+		}
+
+		point := ForecastData{
+			ForecastTime: time.Unix(slot.Dt, 0).UTC(),
+			Temperature:  slot.Main.Temp,
+			Humidity:     slot.Main.Humidity,
+			WindSpeed:    slot.Wind.Speed,
+			Conditions:   conditions,
+		}
+		if slot.Rain.ThreeH > 0 {
+			rain := slot.Rain.ThreeH
+			point.RainVolume3h = &rain
+		}
+		if slot.Snow.ThreeH > 0 {
+			snow := slot.Snow.ThreeH
+			point.SnowVolume3h = &snow
+		}
+		forecast = append(forecast, point)
+	}
+
+	return forecast, nil
+}
+
+// FetchAQIForecast makes an API call to OpenWeatherMap to get the predicted
+// air pollution for the given latitude and longitude.
+func (c *OpenWeatherMapClient) FetchAQIForecast(ctx context.Context, lat, lon float64) ([]ForecastAQIData, error) {
+	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/air_pollution/forecast?lat=%f&lon=%f&appid=%s", lat, lon, c.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AQI forecast HTTP request: %w", err)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute AQI forecast HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenWeatherMap AQI forecast API returned non-OK status: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var owmResponse struct {
+		List []struct {
+			Dt   int64 `json:"dt"`
+			Main struct {
+				AQI int `json:"aqi"`
+			} `json:"main"`
+			Components struct {
+				CO    float64 `json:"co"`
+				NO    float64 `json:"no"`
+				NO2   float64 `json:"no2"`
+				O3    float64 `json:"o3"`
+				SO2   float64 `json:"so2"`
+				PM2_5 float64 `json:"pm2_5"`
+				PM10  float64 `json:"pm10"`
+				NH3   float64 `json:"nh3"`
+			} `json:"components"`
+		} `json:"list"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&owmResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenWeatherMap AQI forecast API response: %w", err)
+	}
+
+	forecast := make([]ForecastAQIData, 0, len(owmResponse.List))
+	for _, slot := range owmResponse.List {
+		forecast = append(forecast, ForecastAQIData{
+			ForecastTime: time.Unix(slot.Dt, 0).UTC(),
+			AQI:          slot.Main.AQI,
+			CO:           slot.Components.CO,
+			NO:           slot.Components.NO,
+			NO2:          slot.Components.NO2,
+			O3:           slot.Components.O3,
+			SO2:          slot.Components.SO2,
+			PM2_5:        slot.Components.PM2_5,
+			PM10:         slot.Components.PM10,
+			NH3:          slot.Components.NH3,
+		})
+	}
+
+	return forecast, nil
+}
+
+// aqiForecastMatchTolerance bounds how far apart a weather slot's
+// forecast_time and an AQI slot's forecast_time may be and still be
+// considered the same point in time. OWM's /forecast returns 3-hour slots
+// but /air_pollution/forecast returns hourly slots, so the two lists don't
+// share timestamps; half the weather cadence keeps each weather slot
+// matched to whichever AQI slot is actually closest to it.
+const aqiForecastMatchTolerance = 90 * time.Minute
+
+// mergeForecasts zips predicted weather slots with predicted AQI slots for
+// the same city into the documents stored in the city_forecast collection.
+// Slots are matched by nearest forecast_time within aqiForecastMatchTolerance,
+// since the two source endpoints run at different cadences; a weather slot
+// with no AQI match within tolerance gets AQIAvailable: false rather than a
+// zero-filled (and misleadingly "clean air") AQI reading.
+func mergeForecasts(city CityInfo, weather []ForecastData, aqi []ForecastAQIData, issuedAt time.Time) []ForecastPoint {
+	points := make([]ForecastPoint, 0, len(weather))
+	for _, w := range weather {
+		a, ok := nearestAQIForecast(w.ForecastTime, aqi, aqiForecastMatchTolerance)
+		point := ForecastPoint{
+			City:         city.Name,
+			Latitude:     city.Lat,
+			Longitude:    city.Lon,
+			ForecastTime: w.ForecastTime,
+			IssuedAt:     issuedAt,
+			Temperature:  w.Temperature,
+			Humidity:     w.Humidity,
+			WindSpeed:    w.WindSpeed,
+			Conditions:   w.Conditions,
+			RainVolume3h: w.RainVolume3h,
+			SnowVolume3h: w.SnowVolume3h,
+			AQIAvailable: ok,
+		}
+		if ok {
+			point.AQI = a.AQI
+			point.CO = a.CO
+			point.NO = a.NO
+			point.NO2 = a.NO2
+			point.O3 = a.O3
+			point.SO2 = a.SO2
+			point.PM2_5 = a.PM2_5
+			point.PM10 = a.PM10
+			point.NH3 = a.NH3
+		}
+		points = append(points, point)
+	}
+	return points
+}
+
+// nearestAQIForecast returns the slot in aqi whose forecast_time is closest
+// to t, provided it's within tolerance. ok is false if aqi is empty or
+// every slot falls outside tolerance.
+func nearestAQIForecast(t time.Time, aqi []ForecastAQIData, tolerance time.Duration) (slot ForecastAQIData, ok bool) {
+	var bestDiff time.Duration
+	for _, a := range aqi {
+		diff := t.Sub(a.ForecastTime)
+		if diff < 0 {
+			diff = -diff
+		}
+		if diff > tolerance {
+			continue
+		}
+		if !ok || diff < bestDiff {
+			slot, bestDiff, ok = a, diff, true
+		}
+	}
+	return slot, ok
+}
+
+// upsertForecastPoint replaces any stale prediction for the same
+// (city, forecast_time) with the freshly issued one.
+func upsertForecastPoint(collection *mongo.Collection, point ForecastPoint) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	filter := bson.M{"city": point.City, "forecast_time": point.ForecastTime}
+	_, err := collection.ReplaceOne(ctx, filter, point, options.Replace().SetUpsert(true))
+	return err
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// owmGroupBatchSize is OpenWeatherMap's documented limit on the number of
+// city IDs accepted by a single /group request.
+const owmGroupBatchSize = 20
+
+// FetchWeatherGroup fetches current weather for every city in ids using
+// OpenWeatherMap's /group endpoint, chunking the request into batches of
+// owmGroupBatchSize. It returns the weather keyed by OWM city ID so the
+// caller can match it back to its CityInfo entries. If a later batch fails,
+// the results already collected from earlier, successful batches are
+// returned alongside the error rather than discarded, so a caller with more
+// than owmGroupBatchSize cities can still use the ones that succeeded.
+func (c *OpenWeatherMapClient) FetchWeatherGroup(ctx context.Context, ids []int) (map[int]WeatherData, error) {
+	results := make(map[int]WeatherData, len(ids))
+
+	for start := 0; start < len(ids); start += owmGroupBatchSize {
+		end := start + owmGroupBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+
+		batch, err := c.fetchWeatherGroupBatch(ctx, ids[start:end])
+		if err != nil {
+			return results, fmt.Errorf("group weather batch [%d:%d] failed: %w", start, end, err)
+		}
+		for id, data := range batch {
+			results[id] = data
+		}
+	}
+
+	return results, nil
+}
+
+// fetchWeatherGroupBatch makes a single call to /group for up to
+// owmGroupBatchSize city IDs.
+func (c *OpenWeatherMapClient) fetchWeatherGroupBatch(ctx context.Context, ids []int) (map[int]WeatherData, error) {
+	csv := make([]string, len(ids))
+	for i, id := range ids {
+		csv[i] = strconv.Itoa(id)
+	}
+
+	url := fmt.Sprintf("https://api.openweathermap.org/data/2.5/group?id=%s&units=metric&appid=%s", strings.Join(csv, ","), c.APIKey)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create group weather HTTP request: %w", err)
+	}
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute group weather HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("OpenWeatherMap group API returned non-OK status: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var owmResponse struct {
+		List []struct {
+			ID   int `json:"id"`
+			Main struct {
+				Temp     float64 `json:"temp"`
+				Pressure int     `json:"pressure"`
+				Humidity int     `json:"humidity"`
+			} `json:"main"`
+			Wind struct {
+				Speed float64 `json:"speed"`
+				Deg   int     `json:"deg"`
+			} `json:"wind"`
+			Clouds struct {
+				All int `json:"all"`
+			} `json:"clouds"`
+			Weather []struct {
+				Description string `json:"description"`
+			} `json:"weather"`
+		} `json:"list"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&owmResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenWeatherMap group API response: %w", err)
+	}
+
+	results := make(map[int]WeatherData, len(owmResponse.List))
+	for _, entry := range owmResponse.List {
+		conditions := ""
+		if len(entry.Weather) > 0 {
+			conditions = entry.Weather[0].Description
+		}
+		results[entry.ID] = WeatherData{
+			Temperature: entry.Main.Temp,
+			Pressure:    entry.Main.Pressure,
+			Humidity:    entry.Main.Humidity,
+			Cloudiness:  entry.Clouds.All,
+			WindSpeed:   entry.Wind.Speed,
+			WindDegree:  entry.Wind.Deg,
+			Conditions:  conditions,
+		}
+	}
+
+	return results, nil
+}
+
+// fetchAQIPool fetches air-pollution data for every city in cities using a
+// bounded pool of concurrency workers, each issuing the existing
+// single-point /air_pollution call. This keeps per-tick AQI calls from
+// spiking to one goroutine per city, since OWM has no batched equivalent of
+// the weather /group endpoint.
+func fetchAQIPool(ctx context.Context, client *OpenWeatherMapClient, cities []CityInfo, concurrency int) map[string]*AQIData {
+	jobs := make(chan CityInfo)
+	results := make(map[string]*AQIData, len(cities))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for i := 0; i < concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for city := range jobs {
+				aqiData, err := client.FetchAQI(ctx, city.Lat, city.Lon)
+				if err != nil {
+					continue
+				}
+				mu.Lock()
+				results[city.Name] = aqiData
+				mu.Unlock()
+			}
+		}()
+	}
+
+	for _, city := range cities {
+		jobs <- city
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results
+}
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"time"
+
+	"github.com/sony/gobreaker"
+)
+
+// Provider is implemented by anything that can supply current weather and
+// AQI readings for a coordinate, so the ingestion loop can fall back to a
+// different vendor when one is unavailable instead of skipping the tick.
+type Provider interface {
+	Name() string
+	FetchWeather(ctx context.Context, lat, lon float64) (*WeatherData, error)
+	FetchAQI(ctx context.Context, lat, lon float64) (*AQIData, error)
+	// ValidateWeather checks a reading this provider produced. It exists
+	// per-provider because validateWeatherData's full range checks assume
+	// fields (pressure, visibility, cloudiness) that not every provider
+	// populates.
+	ValidateWeather(data *WeatherData) bool
+}
+
+// Name identifies OpenWeatherMap as the source of a document.
+func (c *OpenWeatherMapClient) Name() string {
+	return "openweathermap"
+}
+
+// ValidateWeather applies the full range checks, since OWM populates every
+// field on WeatherData.
+func (c *OpenWeatherMapClient) ValidateWeather(data *WeatherData) bool {
+	return validateWeatherData(data)
+}
+
+// MetNorwayClient fetches current weather from MET Norway's Locationforecast
+// API. MET Norway has no air-pollution product, so FetchAQI always returns
+// an error; it exists purely to satisfy the Provider interface. When its
+// weather is valid, FetchCityReading returns that weather with no AQI
+// reading rather than moving on to another provider.
+type MetNorwayClient struct {
+	Client *http.Client
+	// UserAgent identifies the application per MET Norway's terms of
+	// service, which require an identifying User-Agent on every request.
+	UserAgent string
+}
+
+// NewMetNorwayClient creates a MetNorwayClient. userAgent should identify
+// the application and a contact, e.g. "lahore-aqi-detection/1.0 contact@example.com".
+func NewMetNorwayClient(userAgent string) *MetNorwayClient {
+	return &MetNorwayClient{
+		Client:    &http.Client{Timeout: 10 * time.Second},
+		UserAgent: userAgent,
+	}
+}
+
+// Name identifies MET Norway as the source of a document.
+func (c *MetNorwayClient) Name() string {
+	return "met.no"
+}
+
+// FetchWeather makes an API call to MET Norway's Locationforecast compact
+// endpoint and extracts the instant weather details for the given
+// coordinate.
+func (c *MetNorwayClient) FetchWeather(ctx context.Context, lat, lon float64) (*WeatherData, error) {
+	url := fmt.Sprintf("https://api.met.no/weatherapi/locationforecast/2.0/compact?lat=%f&lon=%f", lat, lon)
+
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create MET Norway HTTP request: %w", err)
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to execute MET Norway HTTP request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("MET Norway API returned non-OK status: %d, response: %s", resp.StatusCode, string(body))
+	}
+
+	var metResponse struct {
+		Properties struct {
+			Timeseries []struct {
+				Data struct {
+					Instant struct {
+						Details struct {
+							AirTemperature    float64 `json:"air_temperature"`
+							RelativeHumidity  float64 `json:"relative_humidity"`
+							WindSpeed         float64 `json:"wind_speed"`
+							WindFromDirection float64 `json:"wind_from_direction"`
+						} `json:"details"`
+					} `json:"instant"`
+				} `json:"data"`
+			} `json:"timeseries"`
+		} `json:"properties"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&metResponse); err != nil {
+		return nil, fmt.Errorf("failed to decode MET Norway API response: %w", err)
+	}
+
+	if len(metResponse.Properties.Timeseries) == 0 {
+		return nil, errors.New("no timeseries data found in MET Norway response")
+	}
+
+	details := metResponse.Properties.Timeseries[0].Data.Instant.Details
+	return &WeatherData{
+		Temperature: details.AirTemperature,
+		Humidity:    int(details.RelativeHumidity),
+		WindSpeed:   details.WindSpeed,
+		WindDegree:  int(details.WindFromDirection),
+	}, nil
+}
+
+// FetchAQI always fails: MET Norway does not publish an air-pollution
+// product.
+func (c *MetNorwayClient) FetchAQI(ctx context.Context, lat, lon float64) (*AQIData, error) {
+	return nil, errors.New("met.no: air quality data is not available from this provider")
+}
+
+// ValidateWeather only checks the fields FetchWeather actually populates
+// (temperature, humidity, wind speed/degree); MET Norway never reports
+// pressure, visibility, or cloudiness, so those stay at their zero value and
+// must not be checked here.
+func (c *MetNorwayClient) ValidateWeather(data *WeatherData) bool {
+	return validateCoreWeatherData(data)
+}
+
+// ProviderEntry pairs a Provider with the circuit breaker that guards calls
+// to it, so each vendor trips independently of the others.
+type ProviderEntry struct {
+	Provider Provider
+	Breaker  *gobreaker.CircuitBreaker
+}
+
+// NewProviderEntry wraps provider with a fresh circuit breaker named after it.
+func NewProviderEntry(provider Provider, settings gobreaker.Settings) ProviderEntry {
+	settings.Name = provider.Name()
+	return ProviderEntry{
+		Provider: provider,
+		Breaker:  gobreaker.NewCircuitBreaker(settings),
+	}
+}
+
+// CityReading is the combined result of fetching weather and AQI for a
+// city from whichever provider in the chain answered first.
+type CityReading struct {
+	Weather *WeatherData
+	AQI     *AQIData
+	Source  string
+}
+
+// FetchCityReading consults providers in priority order. For each provider
+// it retries up to cfg.MaxRetries times behind that provider's own circuit
+// breaker; if the breaker is open (or every retry is exhausted), it moves on
+// to the next provider instead of failing the whole tick. It returns the
+// first successful reading, tagged with the provider that produced it.
+func FetchCityReading(ctx context.Context, providers []ProviderEntry, city CityInfo, cfg Config) (*CityReading, error) {
+	var errs []error
+
+	for _, entry := range providers {
+		result, err := entry.Breaker.Execute(func() (interface{}, error) {
+			var lastErr error
+			for attempt := 0; attempt < cfg.MaxRetries; attempt++ {
+				if attempt > 0 {
+					delay := cfg.BaseRetryDelay + time.Duration(rand.Float64()*float64(cfg.MaxJitter))
+					time.Sleep(delay)
+				}
+
+				apiCtx, apiCancel := context.WithTimeout(ctx, cfg.APITimeout)
+				weatherData, weatherErr := entry.Provider.FetchWeather(apiCtx, city.Lat, city.Lon)
+				aqiData, aqiErr := entry.Provider.FetchAQI(apiCtx, city.Lat, city.Lon)
+				apiCancel()
+
+				if weatherErr == nil && entry.Provider.ValidateWeather(weatherData) {
+					// AQI support is optional per provider (e.g. MET Norway);
+					// carry on without it rather than retrying forever.
+					if aqiErr != nil || !validateAQIData(aqiData) {
+						aqiData = nil
+					}
+					return &CityReading{Weather: weatherData, AQI: aqiData, Source: entry.Provider.Name()}, nil
+				}
+
+				lastErr = fmt.Errorf("weather error: %v, AQI error: %v", weatherErr, aqiErr)
+			}
+			return nil, fmt.Errorf("all %d retries failed for %s: %w", cfg.MaxRetries, entry.Provider.Name(), lastErr)
+		})
+
+		if err != nil {
+			if errors.Is(err, gobreaker.ErrOpenState) {
+				errs = append(errs, fmt.Errorf("%s: circuit breaker open", entry.Provider.Name()))
+			} else {
+				errs = append(errs, err)
+			}
+			continue
+		}
+
+		return result.(*CityReading), nil
+	}
+
+	return nil, fmt.Errorf("all providers failed for %s: %v", city.Name, errs)
+}
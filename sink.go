@@ -0,0 +1,215 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+
+	influxdb2 "github.com/influxdata/influxdb-client-go/v2"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Sink is the destination for an ingested data point. Concrete
+// implementations decouple fetching from storage so a document can be
+// written to Mongo, a time-series DB, and/or scraped from Prometheus
+// without touching the ingestion loop.
+type Sink interface {
+	Write(ctx context.Context, data IngestedData) error
+	Close() error
+}
+
+// MongoSink writes ingested data to a MongoDB collection, same as the
+// original inline insertWeatherData behavior.
+type MongoSink struct {
+	Collection *mongo.Collection
+}
+
+// NewMongoSink returns a Sink that persists documents to the given collection.
+func NewMongoSink(collection *mongo.Collection) *MongoSink {
+	return &MongoSink{Collection: collection}
+}
+
+// Write inserts the data point as a new document.
+func (s *MongoSink) Write(ctx context.Context, data IngestedData) error {
+	_, err := s.Collection.InsertOne(ctx, data)
+	if err != nil {
+		return fmt.Errorf("mongo sink: failed to insert document for %s: %w", data.City, err)
+	}
+	return nil
+}
+
+// Close is a no-op; the shared *mongo.Client is disconnected by main.
+func (s *MongoSink) Close() error {
+	return nil
+}
+
+// InfluxSink writes ingested data to InfluxDB using line protocol via the
+// blocking write API.
+type InfluxSink struct {
+	client influxdb2.Client
+	org    string
+	bucket string
+}
+
+// NewInfluxSink connects to an InfluxDB instance at url, authenticating with
+// token, and returns a Sink that writes points into org/bucket.
+func NewInfluxSink(url, token, org, bucket string) *InfluxSink {
+	return &InfluxSink{
+		client: influxdb2.NewClient(url, token),
+		org:    org,
+		bucket: bucket,
+	}
+}
+
+// Write records the ingested data point as a single InfluxDB point tagged by
+// city, with coordinates, temperature, humidity, wind speed, conditions, both
+// AQI values and the pollutant concentrations as fields. The other weather
+// fields (feels-like, pressure, visibility, cloudiness, wind degree,
+// precipitation, sunrise/sunset), the dominant pollutant and the source are
+// not written.
+func (s *InfluxSink) Write(ctx context.Context, data IngestedData) error {
+	writeAPI := s.client.WriteAPIBlocking(s.org, s.bucket)
+
+	point := influxdb2.NewPoint(
+		"city_data",
+		map[string]string{"city": data.City},
+		map[string]interface{}{
+			"latitude":    data.Latitude,
+			"longitude":   data.Longitude,
+			"temperature": data.Temperature,
+			"humidity":    data.Humidity,
+			"wind_speed":  data.WindSpeed,
+			"conditions":  data.Conditions,
+			"aqi":         data.AQI,
+			"co":          data.CO,
+			"no":          data.NO,
+			"no2":         data.NO2,
+			"o3":          data.O3,
+			"so2":         data.SO2,
+			"pm2_5":       data.PM2_5,
+			"pm10":        data.PM10,
+			"nh3":         data.NH3,
+			"aqi_us":      data.AQI_US,
+		},
+		data.Timestamp,
+	)
+
+	if err := writeAPI.WritePoint(ctx, point); err != nil {
+		return fmt.Errorf("influx sink: failed to write point for %s: %w", data.City, err)
+	}
+	return nil
+}
+
+// Close flushes any buffered writes and shuts down the Influx client.
+func (s *InfluxSink) Close() error {
+	s.client.Close()
+	return nil
+}
+
+// PrometheusSink exposes the latest reading per city as gauges scraped via
+// /metrics, rather than writing a time series of its own.
+type PrometheusSink struct {
+	registry  *prometheus.Registry
+	server    *http.Server
+	aqi       *prometheus.GaugeVec
+	pm25      *prometheus.GaugeVec
+	pm10      *prometheus.GaugeVec
+	temp      *prometheus.GaugeVec
+	humidity  *prometheus.GaugeVec
+	windSpeed *prometheus.GaugeVec
+}
+
+// NewPrometheusSink registers the city-labeled gauges and starts serving
+// /metrics on addr (e.g. ":9100") in a background goroutine.
+func NewPrometheusSink(addr string) *PrometheusSink {
+	registry := prometheus.NewRegistry()
+
+	gauge := func(name, help string) *prometheus.GaugeVec {
+		g := prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: name, Help: help}, []string{"city"})
+		registry.MustRegister(g)
+		return g
+	}
+
+	sink := &PrometheusSink{
+		registry:  registry,
+		aqi:       gauge("aqi", "US EPA Air Quality Index"),
+		pm25:      gauge("pm2_5", "PM2.5 concentration in micrograms per cubic meter"),
+		pm10:      gauge("pm10", "PM10 concentration in micrograms per cubic meter"),
+		temp:      gauge("temperature", "Temperature in degrees Celsius"),
+		humidity:  gauge("humidity", "Relative humidity percentage"),
+		windSpeed: gauge("wind_speed", "Wind speed in meters per second"),
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", promhttp.HandlerFor(registry, promhttp.HandlerOpts{}))
+	sink.server = &http.Server{Addr: addr, Handler: mux}
+
+	go func() {
+		if err := sink.server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			log.Printf("ERROR: Prometheus sink HTTP server stopped: %v", err)
+		}
+	}()
+
+	return sink
+}
+
+// Write updates the gauges for data.City to the latest reading.
+func (s *PrometheusSink) Write(ctx context.Context, data IngestedData) error {
+	s.aqi.WithLabelValues(data.City).Set(float64(data.AQI_US))
+	s.pm25.WithLabelValues(data.City).Set(data.PM2_5)
+	s.pm10.WithLabelValues(data.City).Set(data.PM10)
+	s.temp.WithLabelValues(data.City).Set(data.Temperature)
+	s.humidity.WithLabelValues(data.City).Set(float64(data.Humidity))
+	s.windSpeed.WithLabelValues(data.City).Set(data.WindSpeed)
+	return nil
+}
+
+// Close shuts down the /metrics HTTP server.
+func (s *PrometheusSink) Close() error {
+	return s.server.Close()
+}
+
+// MultiSink fans a single write out to every configured Sink, so the
+// ingestion loop doesn't need to know which backends are enabled.
+type MultiSink struct {
+	sinks []Sink
+}
+
+// NewMultiSink returns a Sink that fans out writes to every sink in sinks.
+func NewMultiSink(sinks ...Sink) *MultiSink {
+	return &MultiSink{sinks: sinks}
+}
+
+// Write calls Write on every underlying sink, collecting (not short-
+// circuiting on) individual failures so one bad backend doesn't block the
+// others.
+func (m *MultiSink) Write(ctx context.Context, data IngestedData) error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Write(ctx, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("multi sink: %d of %d sinks failed: %v", len(errs), len(m.sinks), errs)
+	}
+	return nil
+}
+
+// Close closes every underlying sink, collecting (not short-circuiting on)
+// individual failures.
+func (m *MultiSink) Close() error {
+	var errs []error
+	for _, sink := range m.sinks {
+		if err := sink.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("multi sink: %d of %d sinks failed to close: %v", len(errs), len(m.sinks), errs)
+	}
+	return nil
+}